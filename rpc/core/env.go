@@ -0,0 +1,14 @@
+package core
+
+import "github.com/cometbft/cometbft/state"
+
+// Environment holds the dependencies this package's RPC handlers need.
+// env is populated by node startup before the RPC server starts, the same
+// way every rpc/core handler reaches its dependencies, so handlers can stay
+// flat functions of *rpctypes.Context instead of threading state through
+// method receivers.
+type Environment struct {
+	Pruner *state.Pruner
+}
+
+var env *Environment
@@ -0,0 +1,23 @@
+package pruning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	pruningv1 "github.com/cometbft/cometbft/api/cometbft/services/pruning/v1"
+)
+
+// RegisterGatewayHandler wires up the REST/JSON counterpart of the pruning
+// gRPC service, proxying HTTP+JSON requests on mux to the gRPC service
+// listening at grpcEndpoint. This is the REST half of the "gRPC/REST
+// companion service" this package was meant to provide; only the gRPC half
+// had been wired up until now.
+func RegisterGatewayHandler(ctx context.Context, mux *runtime.ServeMux, grpcEndpoint string, opts ...grpc.DialOption) error {
+	if err := pruningv1.RegisterPruningServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+		return fmt.Errorf("failed to register pruning service REST gateway: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Package pruning implements the gRPC/REST surface that lets an
+// out-of-process data companion drive CometBFT's retain heights directly,
+// without going through an in-process ABCI hook. It is the RPC counterpart
+// to state.Pruner's Set*RetainHeight methods and implements the
+// PruningService defined in proto/cometbft/services/pruning/v1.
+package pruning
+
+import (
+	"context"
+
+	pruningv1 "github.com/cometbft/cometbft/api/cometbft/services/pruning/v1"
+	"github.com/cometbft/cometbft/state"
+)
+
+// Service implements pruningv1.PruningServiceServer on top of a
+// state.Pruner. All validation (height must be positive and no greater than
+// the current height, no lower than the base height for block retain
+// heights, and not lower than the last accepted value for that source) is
+// delegated to the Pruner, so companions are held to exactly the same rules
+// as in-process callers.
+type Service struct {
+	pruningv1.UnimplementedPruningServiceServer
+
+	pruner *state.Pruner
+}
+
+// NewService constructs a pruning RPC service backed by pruner.
+func NewService(pruner *state.Pruner) *Service {
+	return &Service{pruner: pruner}
+}
+
+func (s *Service) SetApplicationRetainHeight(
+	_ context.Context,
+	req *pruningv1.SetApplicationRetainHeightRequest,
+) (*pruningv1.SetApplicationRetainHeightResponse, error) {
+	if err := s.pruner.SetApplicationRetainHeight(req.Height); err != nil {
+		return nil, err
+	}
+	return &pruningv1.SetApplicationRetainHeightResponse{}, nil
+}
+
+func (s *Service) SetCompanionBlockRetainHeight(
+	_ context.Context,
+	req *pruningv1.SetCompanionBlockRetainHeightRequest,
+) (*pruningv1.SetCompanionBlockRetainHeightResponse, error) {
+	if err := s.pruner.SetCompanionRetainHeight(req.Height); err != nil {
+		return nil, err
+	}
+	return &pruningv1.SetCompanionBlockRetainHeightResponse{}, nil
+}
+
+func (s *Service) SetCompanionABCIResultsRetainHeight(
+	_ context.Context,
+	req *pruningv1.SetCompanionABCIResultsRetainHeightRequest,
+) (*pruningv1.SetCompanionABCIResultsRetainHeightResponse, error) {
+	if err := s.pruner.SetABCIResRetainHeight(req.Height); err != nil {
+		return nil, err
+	}
+	return &pruningv1.SetCompanionABCIResultsRetainHeightResponse{}, nil
+}
+
+func (s *Service) GetRetainHeights(
+	_ context.Context,
+	_ *pruningv1.GetRetainHeightsRequest,
+) (*pruningv1.GetRetainHeightsResponse, error) {
+	rh := s.pruner.GetRetainHeights()
+	return &pruningv1.GetRetainHeightsResponse{
+		AppRetainHeight:             rh.App,
+		CompanionRetainHeight:       rh.Companion,
+		AbciResultsRetainHeight:     rh.ABCIResults,
+		SnapshotRetainHeight:        rh.Snapshot,
+		LastBlockPrunedHeight:       rh.LastBlockPruned,
+		LastAbciResultsPrunedHeight: rh.LastABCIResultsPruned,
+	}, nil
+}
+
+// WatchRetainHeights streams a WatchRetainHeightsResponse for every update
+// delivered by state.Pruner.SubscribeRetainHeightUpdates (updates that find
+// the subscription's buffer full are dropped there), so a companion can
+// pipeline "I've indexed up to H, safe to prune" messages without polling
+// GetRetainHeights.
+func (s *Service) WatchRetainHeights(
+	_ *pruningv1.WatchRetainHeightsRequest,
+	stream pruningv1.PruningService_WatchRetainHeightsServer,
+) error {
+	updates, unsubscribe := s.pruner.SubscribeRetainHeightUpdates()
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case u := <-updates:
+			if err := stream.Send(&pruningv1.WatchRetainHeightsResponse{
+				Source: u.Source,
+				Height: u.Height,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package pruning
+
+import (
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+
+	pruningv1 "github.com/cometbft/cometbft/api/cometbft/services/pruning/v1"
+	"github.com/cometbft/cometbft/libs/log"
+	"github.com/cometbft/cometbft/state"
+)
+
+// Config controls whether, and where, the pruning RPC service is served.
+// It follows the same privileged-API pattern as CometBFT's other
+// companion-facing gRPC services (the block and block-results services):
+// the pruning service is off by default, and when enabled it is bound to
+// its own listen address rather than the node's public RPC address, so
+// operators gate access by placing it on a private network reachable only
+// by trusted data companions rather than by an in-band auth token.
+type Config struct {
+	Enabled       bool
+	ListenAddress string
+}
+
+// StartServer starts the pruning gRPC service on its own listener if
+// cfg.Enabled, registering it the same way node startup registers the
+// node's other privileged gRPC services. It returns (nil, nil) when the
+// service is disabled, so callers can unconditionally defer Stop on a
+// non-nil result. Without this, Service was constructible but never
+// reachable from a running node.
+func StartServer(cfg Config, pruner *state.Pruner, logger log.Logger) (*grpc.Server, error) {
+	if !cfg.Enabled {
+		logger.Info("pruning RPC service is disabled")
+		return nil, nil
+	}
+	if cfg.ListenAddress == "" {
+		return nil, fmt.Errorf("pruning RPC service is enabled but has no listen address configured")
+	}
+
+	lis, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s for the pruning RPC service: %w", cfg.ListenAddress, err)
+	}
+
+	server := grpc.NewServer()
+	pruningv1.RegisterPruningServiceServer(server, NewService(pruner))
+
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			logger.Error("pruning RPC service stopped serving", "err", err)
+		}
+	}()
+	logger.Info("pruning RPC service listening", "addr", cfg.ListenAddress)
+	return server, nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	rpctypes "github.com/cometbft/cometbft/rpc/jsonrpc/types"
+)
+
+// ResultPruningInfo mirrors ctypes.ResultPruningInfo: it reports the retain
+// heights currently in effect for every pruning source, and how far
+// pruning has actually progressed, mirroring state.Pruner.GetRetainHeights.
+type ResultPruningInfo struct {
+	ApplicationRetainHeight     int64 `json:"application_retain_height"`
+	CompanionRetainHeight       int64 `json:"companion_retain_height"`
+	ABCIResultsRetainHeight     int64 `json:"abci_results_retain_height"`
+	SnapshotRetainHeight        int64 `json:"snapshot_retain_height"`
+	LastBlockPrunedHeight       int64 `json:"last_block_pruned_height"`
+	LastABCIResultsPrunedHeight int64 `json:"last_abci_results_pruned_height"`
+}
+
+// PruningInfo reports the retain heights currently in effect for every
+// pruning source and how far pruning has actually progressed, so operators
+// can diagnose stuck or lagging pruning from the node's own RPC instead of
+// grepping debug logs or standing up the companion-facing pruning gRPC
+// service just to call GetRetainHeights.
+//
+// More: https://docs.cometbft.com/main/rpc/#/Info/pruning_info
+func PruningInfo(_ *rpctypes.Context) (*ResultPruningInfo, error) {
+	rh := env.Pruner.GetRetainHeights()
+	return &ResultPruningInfo{
+		ApplicationRetainHeight:     rh.App,
+		CompanionRetainHeight:       rh.Companion,
+		ABCIResultsRetainHeight:     rh.ABCIResults,
+		SnapshotRetainHeight:        rh.Snapshot,
+		LastBlockPrunedHeight:       rh.LastBlockPruned,
+		LastABCIResultsPrunedHeight: rh.LastABCIResultsPruned,
+	}, nil
+}
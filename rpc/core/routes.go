@@ -0,0 +1,12 @@
+package core
+
+import (
+	rpcserver "github.com/cometbft/cometbft/rpc/jsonrpc/server"
+)
+
+// Routes maps RPC method names to their handlers; the JSON-RPC/websocket
+// server dispatches against this map, so a handler that isn't listed here
+// is unreachable regardless of what it implements.
+var Routes = map[string]*rpcserver.RPCFunc{
+	"pruning_info": rpcserver.NewRPCFunc(PruningInfo, ""),
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/cometbft/cometbft/config"
@@ -13,12 +14,38 @@ var (
 	AppRetainHeightKey            = []byte("AppRetainHeightKey")
 	CompanionBlockRetainHeightKey = []byte("DCBlockRetainHeightKey")
 	ABCIResultsRetainHeightKey    = []byte("ABCIResRetainHeightKey")
+	LastBlockPrunedHeightKey      = []byte("LastBlockPrunedHeightKey")
+	LastABCIResPrunedHeightKey    = []byte("LastABCIResPrunedHeightKey")
+	SnapshotBlockRetainHeightKey  = []byte("SnapshotBlockRetainHeightKey")
 )
 
+// PrunerObserver is notified of pruning progress as it happens, so that RPC
+// endpoints or monitoring systems (e.g. Prometheus metrics) can report it
+// without operators having to grep debug logs.
+type PrunerObserver interface {
+	// PrunedBlocks is called after a successful block pruning pass with the
+	// range that was pruned (from is inclusive, to is exclusive: to is the
+	// first height kept) and the number of blocks actually removed.
+	PrunedBlocks(from, to int64, count uint64)
+	// PrunedABCIResponses is called after a successful ABCI response
+	// pruning pass with the height pruned up to and the number removed.
+	PrunedABCIResponses(to int64, count uint64)
+}
+
+// retainHeightChCapacity is the size of the buffered channels used to notify
+// the pruning routine of newly requested retain heights. It is kept small on
+// purpose: only the latest requested height matters, so a full channel just
+// means the routine hasn't caught up yet.
+const retainHeightChCapacity = 1
+
 // Pruner is a service that reads the retain heights for blocks, state and ABCI
 // results from the database and prunes the corresponding data based on the
-// minimum retain height set. The service sleeps between each run based on the
-// configured pruner interval, and re-evaluates the retain height.
+// minimum retain height set. Rather than polling on a fixed interval, the
+// pruning routine is woken up as soon as one of the retain heights advances,
+// so operators setting aggressive retain heights don't have to wait out the
+// configured interval. The interval is kept as a fallback so pruning still
+// makes progress even if no retain height update ever arrives (e.g. a
+// consensus-params driven retain height that only changes on upgrade).
 type Pruner struct {
 	service.BaseService
 	logger log.Logger
@@ -27,10 +54,62 @@ type Pruner struct {
 	// State store to prune state from
 	stateStore Store
 	interval   time.Duration
+
+	// appRetainHeightCh, companionRetainHeightCh, abciResRetainHeightCh and
+	// snapshotRetainHeightCh carry newly requested retain heights from the
+	// Set* methods to the pruning routine. They are buffered with
+	// retainHeightChCapacity and a send that would block is dropped (and
+	// logged at error level) since only the latest height matters and the
+	// routine will pick it up on its next pass through FindMinRetainHeight.
+	appRetainHeightCh       chan int64
+	companionRetainHeightCh chan int64
+	abciResRetainHeightCh   chan int64
+	snapshotRetainHeightCh  chan int64
+
+	minRetainBlocks uint64
+
+	// snapshotInterval and snapshotKeepRecent let the pruner derive a
+	// snapshot retain height itself (currentHeight - snapshotInterval *
+	// snapshotKeepRecent) without the state-sync snapshot subsystem having
+	// to call SetSnapshotRetainHeight. Zero disables the derivation.
+	snapshotInterval   uint64
+	snapshotKeepRecent uint32
+
+	// batchSize, if non-zero, caps how many heights pruneBlocks removes in
+	// one go. See PrunerBatchSize.
+	batchSize uint64
+
+	// observers are notified after each successful pruning pass. See
+	// PrunerObserver and WithObserver.
+	observers []PrunerObserver
+
+	// subscribers receive a RetainHeightUpdate every time a retain height
+	// source advances. Unlike the dedicated per-source channels above,
+	// subscribers are purely observational (e.g. rpc/core/pruning's
+	// WatchRetainHeights) and never drive the pruning routine itself.
+	subscribersMu sync.Mutex
+	subscribers   map[chan<- RetainHeightUpdate]struct{}
+}
+
+// RetainHeightUpdate describes a single retain height source advancing, as
+// delivered to SubscribeRetainHeightUpdates subscribers.
+type RetainHeightUpdate struct {
+	// Source identifies which retain height advanced: "app", "companion",
+	// "abci_results", or "snapshot".
+	Source string
+	Height int64
 }
 
 type prunerConfig struct {
 	interval time.Duration
+	// minRetainBlocks is the minimum number of blocks that must always be
+	// kept locally, regardless of what the application or data companion
+	// request. A value of 0 disables the floor.
+	minRetainBlocks    uint64
+	snapshotInterval   uint64
+	snapshotKeepRecent uint32
+	batchSize          uint64
+	observers          []PrunerObserver
 }
 
 func defaultPrunerConfig() *prunerConfig {
@@ -47,16 +126,72 @@ func PrunerInterval(t time.Duration) PrunerOption {
 	return func(p *prunerConfig) { p.interval = t }
 }
 
+// MinRetainBlocks sets a floor, in number of blocks behind the chain head,
+// below which the pruner will never prune, regardless of the retain heights
+// requested by the application or the data companion. This gives operators
+// a single knob to guarantee local retention of recent blocks even when the
+// ABCI app or companion request aggressive pruning. The floor never goes
+// below what is required to verify evidence (see FindMinRetainHeight).
+func MinRetainBlocks(n uint64) PrunerOption {
+	return func(p *prunerConfig) { p.minRetainBlocks = n }
+}
+
+// WithObserver registers a PrunerObserver that is notified after each
+// successful pruning pass. It may be given multiple times to register
+// several observers (e.g. one for Prometheus metrics, one for RPC status).
+func WithObserver(o PrunerObserver) PrunerOption {
+	return func(p *prunerConfig) { p.observers = append(p.observers, o) }
+}
+
+// SnapshotInterval and SnapshotKeepRecent, together, let the pruner derive a
+// snapshot retain height on its own -- currentHeight - SnapshotInterval *
+// SnapshotKeepRecent -- so that a recent snapshot chunk is always servable
+// without the state-sync snapshot subsystem calling SetSnapshotRetainHeight
+// directly. They should be given the same values as the snapshot manager's
+// own interval/keep-recent configuration. A zero SnapshotInterval disables
+// the derivation.
+func SnapshotInterval(n uint64) PrunerOption {
+	return func(p *prunerConfig) { p.snapshotInterval = n }
+}
+
+// SnapshotKeepRecent sets how many recent snapshots must remain servable;
+// see SnapshotInterval.
+func SnapshotKeepRecent(n uint32) PrunerOption {
+	return func(p *prunerConfig) { p.snapshotKeepRecent = n }
+}
+
+// PrunerBatchSize caps how many heights pruneBlocks removes in a single
+// pass, splitting [base, retainHeight] into chunks of at most n heights
+// instead of pruning the whole range at once. This keeps a single pruning
+// pass from holding DB write locks for a long time when the gap between
+// the current base and the retain height is very large (e.g. the first
+// prune after a long time, or after an operator raises the retain height
+// by millions of blocks at once). A value of 0 (the default) disables
+// batching and prunes the whole range in one call, as before.
+func PrunerBatchSize(n uint64) PrunerOption {
+	return func(p *prunerConfig) { p.batchSize = n }
+}
+
 func NewPruner(stateStore Store, bs BlockStore, logger log.Logger, options ...PrunerOption) *Pruner {
 	cfg := defaultPrunerConfig()
 	for _, opt := range options {
 		opt(cfg)
 	}
 	p := &Pruner{
-		bs:         bs,
-		stateStore: stateStore,
-		logger:     logger,
-		interval:   cfg.interval,
+		bs:                      bs,
+		stateStore:              stateStore,
+		logger:                  logger,
+		interval:                cfg.interval,
+		appRetainHeightCh:       make(chan int64, retainHeightChCapacity),
+		companionRetainHeightCh: make(chan int64, retainHeightChCapacity),
+		abciResRetainHeightCh:   make(chan int64, retainHeightChCapacity),
+		snapshotRetainHeightCh:  make(chan int64, retainHeightChCapacity),
+		minRetainBlocks:         cfg.minRetainBlocks,
+		snapshotInterval:        cfg.snapshotInterval,
+		snapshotKeepRecent:      cfg.snapshotKeepRecent,
+		batchSize:               cfg.batchSize,
+		observers:               cfg.observers,
+		subscribers:             make(map[chan<- RetainHeightUpdate]struct{}),
 	}
 	p.BaseService = *service.NewBaseService(logger, "Pruner", p)
 	return p
@@ -108,8 +243,11 @@ func (p *Pruner) SetApplicationRetainHeight(height int64) error {
 	if currentAppRetainHeight > height || (!noCompanionRetainHeight && currentCompanionRetainHeight > height) {
 		return errors.New("cannot set a height lower than previously requested - blocks might have already been pruned")
 	}
-	err = p.stateStore.SaveApplicationRetainHeight(height)
-	return err
+	if err := p.stateStore.SaveApplicationRetainHeight(height); err != nil {
+		return err
+	}
+	p.notifyRetainHeight(p.appRetainHeightCh, "app", height)
+	return nil
 }
 
 // SetCompanionRetainHeight sets the application retain height with some basic
@@ -144,8 +282,11 @@ func (p *Pruner) SetCompanionRetainHeight(height int64) error {
 	if currentCompanionRetainHeight > height || (!noAppRetainHeight && currentAppRetainHeight > height) {
 		return errors.New("cannot set a height lower than previously requested - blocks might have already been pruned")
 	}
-	err = p.stateStore.SaveCompanionBlockRetainHeight(height)
-	return err
+	if err := p.stateStore.SaveCompanionBlockRetainHeight(height); err != nil {
+		return err
+	}
+	p.notifyRetainHeight(p.companionRetainHeightCh, "companion", height)
+	return nil
 }
 
 // SetABCIResRetainHeight sets the retain height for ABCI responses.
@@ -159,88 +300,383 @@ func (p *Pruner) SetABCIResRetainHeight(height int64) error {
 	currentRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
 	if err != nil {
 		if err == ErrKeyNotFound {
-			err = p.stateStore.SaveABCIResRetainHeight(height)
-			return err
+			if err := p.stateStore.SaveABCIResRetainHeight(height); err != nil {
+				return err
+			}
+			p.notifyRetainHeight(p.abciResRetainHeightCh, "abci_results", height)
+			return nil
 		}
 		return err
 	}
 	if currentRetainHeight > height {
 		return errors.New("cannot set a height lower than previously requested - blocks might have already been pruned")
 	}
-	err = p.stateStore.SaveABCIResRetainHeight(height)
-	return err
+	if err := p.stateStore.SaveABCIResRetainHeight(height); err != nil {
+		return err
+	}
+	p.notifyRetainHeight(p.abciResRetainHeightCh, "abci_results", height)
+	return nil
+}
+
+// SetSnapshotRetainHeight tells the pruner that a block is still needed to
+// serve state-sync snapshot chunks, so it must not be pruned below height.
+// It is meant to be called by the state-sync snapshot manager whenever it
+// takes or prunes a snapshot; if the manager's interval/keep-recent
+// parameters were instead given via the SnapshotInterval and
+// SnapshotKeepRecent PrunerOptions, the pruner derives this height itself
+// and callers of this method are unnecessary.
+func (p *Pruner) SetSnapshotRetainHeight(height int64) error {
+	if height <= 0 || height < p.bs.Base() || height > p.bs.Height() {
+		return ErrInvalidHeightValue
+	}
+	currentSnapshotRetainHeight, err := p.stateStore.GetSnapshotRetainHeight()
+	if err != nil {
+		if err == ErrKeyNotFound {
+			currentSnapshotRetainHeight = height
+		} else {
+			return err
+		}
+	}
+	if currentSnapshotRetainHeight > height {
+		return errors.New("cannot set a height lower than previously requested - blocks might have already been pruned")
+	}
+	if err := p.stateStore.SaveSnapshotRetainHeight(height); err != nil {
+		return err
+	}
+	p.notifyRetainHeight(p.snapshotRetainHeightCh, "snapshot", height)
+	return nil
+}
+
+// notifyRetainHeight pushes height onto ch so the pruning routine can react
+// to it immediately. If the routine hasn't drained a previous notification
+// yet, the send is dropped and logged at error level since only the latest height
+// matters for the next pruning pass. It also fans the update out to any
+// SubscribeRetainHeightUpdates subscribers.
+func (p *Pruner) notifyRetainHeight(ch chan int64, source string, height int64) {
+	select {
+	case ch <- height:
+	default:
+		p.logger.Error("retain height notification channel full, dropping signal", "source", source, "height", height)
+	}
+
+	p.subscribersMu.Lock()
+	defer p.subscribersMu.Unlock()
+	for sub := range p.subscribers {
+		select {
+		case sub <- RetainHeightUpdate{Source: source, Height: height}:
+		default:
+			p.logger.Error("retain height subscriber channel full, dropping update", "source", source, "height", height)
+		}
+	}
 }
 
+// SubscribeRetainHeightUpdates registers a channel, buffered for 16 updates,
+// that receives a RetainHeightUpdate every time one of the Set*RetainHeight
+// methods (application, companion, ABCI results, or snapshot) accepts a
+// height; an update that finds the channel full is dropped. A snapshot
+// retain height derived from the SnapshotInterval/SnapshotKeepRecent options
+// is not delivered. It is meant for observational consumers such as
+// rpc/core/pruning's WatchRetainHeights, so they can react immediately
+// instead of polling GetRetainHeights. Call the returned unsubscribe func to
+// stop delivery and release the channel.
+func (p *Pruner) SubscribeRetainHeightUpdates() (ch <-chan RetainHeightUpdate, unsubscribe func()) {
+	updates := make(chan RetainHeightUpdate, 16)
+	p.subscribersMu.Lock()
+	p.subscribers[updates] = struct{}{}
+	p.subscribersMu.Unlock()
+
+	return updates, func() {
+		p.subscribersMu.Lock()
+		delete(p.subscribers, updates)
+		p.subscribersMu.Unlock()
+	}
+}
+
+// pruningRoutine is woken up by a retain height notification on any of the
+// appRetainHeightCh/companionRetainHeightCh/abciResRetainHeightCh/
+// snapshotRetainHeightCh channels,
+// so RPC calls that advance a retain height get acted on right away instead
+// of waiting out the configured interval. The interval is kept as a
+// fallback tick so pruning still makes progress if no notification ever
+// arrives.
 func (p *Pruner) pruningRoutine() {
-	lastHeightPruned := int64(0)
-	lastABCIResPrunedHeight := int64(0)
+	lastHeightPruned, err := p.stateStore.GetLastBlockPrunedHeight()
+	if err != nil && err != ErrKeyNotFound {
+		p.logger.Error("Failed to load last pruned block height, defaulting to 0", "err", err)
+	}
+	lastABCIResPrunedHeight, err := p.stateStore.GetLastABCIResPrunedHeight()
+	if err != nil && err != ErrKeyNotFound {
+		p.logger.Error("Failed to load last pruned ABCI response height, defaulting to 0", "err", err)
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	// Run an immediate pass before waiting on anything: retain heights
+	// persisted from a previous run may already call for pruning, and
+	// without this a restart would otherwise sit idle until either the
+	// full interval elapses or a new Set*RetainHeight call arrives --
+	// exactly the worst-case delay this channel/ticker-driven routine was
+	// written to eliminate.
+	lastHeightPruned = p.attemptBlockPrune(lastHeightPruned)
+	lastABCIResPrunedHeight = p.attemptABCIResPrune(lastABCIResPrunedHeight)
+
 	for {
 		select {
 		case <-p.Quit():
 			return
-		default:
-			retainHeight := p.FindMinRetainHeight()
-			if retainHeight != lastHeightPruned {
-				pruned, evRetainHeight, err := p.pruneBlocks(retainHeight)
-				if err != nil {
-					p.logger.Error("Failed to prune blocks", "err", err)
-				} else {
-					p.logger.Debug("Pruned block(s)", "height", pruned, "evidenceRetainHeight", evRetainHeight)
-				}
-				lastHeightPruned = retainHeight
+		case <-p.appRetainHeightCh:
+			lastHeightPruned = p.attemptBlockPrune(lastHeightPruned)
+		case <-p.companionRetainHeightCh:
+			lastHeightPruned = p.attemptBlockPrune(lastHeightPruned)
+		case <-p.snapshotRetainHeightCh:
+			lastHeightPruned = p.attemptBlockPrune(lastHeightPruned)
+		case <-p.abciResRetainHeightCh:
+			lastABCIResPrunedHeight = p.attemptABCIResPrune(lastABCIResPrunedHeight)
+		case <-ticker.C:
+			lastHeightPruned = p.attemptBlockPrune(lastHeightPruned)
+			lastABCIResPrunedHeight = p.attemptABCIResPrune(lastABCIResPrunedHeight)
+		}
+	}
+}
+
+// attemptBlockPrune re-evaluates the minimum retain height and, if it has
+// moved on from lastHeightPruned, prunes blocks up to it. It returns the
+// block store's base after pruning (lastHeightPruned unchanged if there was
+// nothing to prune or pruning failed) so the caller can update its
+// bookkeeping.
+func (p *Pruner) attemptBlockPrune(lastHeightPruned int64) int64 {
+	retainHeight := p.FindMinRetainHeight()
+	if retainHeight == lastHeightPruned {
+		return lastHeightPruned
+	}
+	pruned, evRetainHeight, err := p.pruneBlocks(retainHeight)
+	if err != nil {
+		p.logger.Error("Failed to prune blocks", "err", err)
+		return lastHeightPruned
+	}
+	p.logger.Debug("Pruned block(s)", "height", pruned, "evidenceRetainHeight", evRetainHeight)
+	// pruneBlocks persists progress and notifies observers per batch, so
+	// base, not retainHeight, is where the drain actually got to: if Quit()
+	// fired mid-drain the two can differ, and bs.Base() reflects the real
+	// progress made either way.
+	return p.bs.Base()
+}
+
+// attemptABCIResPrune prunes ABCI responses up to the currently configured
+// retain height, if it has moved on from lastABCIResPrunedHeight.
+func (p *Pruner) attemptABCIResPrune(lastABCIResPrunedHeight int64) int64 {
+	abciResRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
+	if err != nil {
+		return lastABCIResPrunedHeight
+	}
+	if abciResRetainHeight == lastABCIResPrunedHeight {
+		return lastABCIResPrunedHeight
+	}
+	pruned, err := p.stateStore.PruneABCIResponses(abciResRetainHeight)
+	if err != nil {
+		p.logger.Error("Failed to prune ABCI responses", "err", err)
+		return lastABCIResPrunedHeight
+	}
+	p.logger.Debug("Number of ABCI responses pruned: ", "pruned", pruned)
+	if err := p.stateStore.SaveLastABCIResPrunedHeight(abciResRetainHeight); err != nil {
+		p.logger.Error("Failed to persist last pruned ABCI response height", "err", err)
+	}
+	for _, o := range p.observers {
+		o.PrunedABCIResponses(abciResRetainHeight, pruned)
+	}
+	return abciResRetainHeight
+}
+
+// RetainHeights reports the retain height currently in effect for every
+// source the pruner considers, plus how far pruning has actually
+// progressed. Unset sources and not-yet-available progress are reported as
+// 0. It backs the GetRetainHeights RPC exposed to data companions by
+// rpc/core/pruning.
+type RetainHeights struct {
+	App                   int64
+	Companion             int64
+	ABCIResults           int64
+	Snapshot              int64
+	LastBlockPruned       int64
+	LastABCIResultsPruned int64
+}
+
+// GetRetainHeights reports the current RetainHeights, so that RPC/monitoring
+// layers can diagnose stuck or lagging pruning without grepping debug logs.
+func (p *Pruner) GetRetainHeights() RetainHeights {
+	var rh RetainHeights
+	if h, err := p.stateStore.GetApplicationRetainHeight(); err == nil {
+		rh.App = h
+	}
+	if h, err := p.stateStore.GetCompanionBlockRetainHeight(); err == nil {
+		rh.Companion = h
+	}
+	if h, err := p.stateStore.GetABCIResRetainHeight(); err == nil {
+		rh.ABCIResults = h
+	}
+	if h, ok := p.snapshotRetainHeight(); ok {
+		rh.Snapshot = h
+	}
+	if h, err := p.stateStore.GetLastBlockPrunedHeight(); err == nil {
+		rh.LastBlockPruned = h
+	}
+	if h, err := p.stateStore.GetLastABCIResPrunedHeight(); err == nil {
+		rh.LastABCIResultsPruned = h
+	}
+	return rh
+}
+
+// If no retain height has been set for a given source (application, data
+// companion, state-sync snapshots) the database will not have a value for
+// its key, and that source is simply left out of consideration. Of the
+// sources that were set, we pick the smallest height, since pruning must
+// respect the most conservative requester. The snapshot retain height is
+// only considered once the application or the data companion has set one;
+// if neither has, 0 is returned (i.e. don't prune).
+//
+// The result is then floored by MinRetainBlocks (if configured) and, in all
+// cases, by the height required to still verify evidence, so that neither an
+// aggressive application/companion/snapshot request nor a high
+// MinRetainBlocks value can ever prune blocks the consensus layer still
+// needs for evidence handling.
+func (p *Pruner) FindMinRetainHeight() int64 {
+	var (
+		retainHeight int64
+		isSet        bool
+	)
+
+	consider := func(height int64, err error) bool {
+		if err != nil {
+			if err != ErrKeyNotFound {
+				return false
 			}
+			return true
+		}
+		if !isSet || height < retainHeight {
+			retainHeight = height
+			isSet = true
+		}
+		return true
+	}
+
+	appRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
+	if !consider(appRetainHeight, err) {
+		return 0
+	}
+	dcRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
+	if !consider(dcRetainHeight, err) {
+		return 0
+	}
+	// The snapshot retain height only ever narrows an existing request from
+	// the application or the data companion -- it is not itself a trigger
+	// to start pruning, since a snapshot has nothing useful to say about
+	// whether pruning should happen at all.
+	if isSet {
+		if snapshotRetainHeight, ok := p.snapshotRetainHeight(); ok {
+			consider(snapshotRetainHeight, nil)
+		}
+	}
+
+	return p.capRetainHeight(retainHeight)
+}
+
+// snapshotRetainHeight returns the height below which state-sync snapshots
+// no longer need blocks to be retained, and whether any such height is
+// currently known. It considers both a height explicitly set via
+// SetSnapshotRetainHeight and one derived from the SnapshotInterval /
+// SnapshotKeepRecent PrunerOptions, taking whichever retains more blocks.
+func (p *Pruner) snapshotRetainHeight() (int64, bool) {
+	var (
+		height int64
+		ok     bool
+	)
+	if stored, err := p.stateStore.GetSnapshotRetainHeight(); err == nil {
+		height, ok = stored, true
+	} else if err != ErrKeyNotFound {
+		p.logger.Error("Failed to load snapshot retain height", "err", err)
+	}
 
-			ABCIResRetainHeight, err := p.stateStore.GetABCIResRetainHeight()
-			if err == nil {
-				if lastABCIResPrunedHeight != ABCIResRetainHeight {
-					pruned, _ := p.stateStore.PruneABCIResponses(ABCIResRetainHeight)
-					p.logger.Debug("Number of ABCI responses pruned: ", "pruned", pruned)
-				}
+	if p.snapshotInterval > 0 && p.snapshotKeepRecent > 0 {
+		if derived := p.bs.Height() - int64(p.snapshotInterval)*int64(p.snapshotKeepRecent); derived > 0 {
+			if !ok || derived < height {
+				height, ok = derived, true
 			}
-			time.Sleep(p.interval)
 		}
 	}
+	return height, ok
 }
 
-// If no retain height has been set by the application or the data companion
-// the database will not have values for the corresponding keys.
-// If both retain heights were set, we pick the smaller one
-// If only one is set we return that one
-func (p *Pruner) FindMinRetainHeight() int64 {
-	var noAppRetainHeightSet bool
-	appRetainHeight, err := p.stateStore.GetApplicationRetainHeight()
+// capRetainHeight lowers retainHeight, if necessary, so that it never goes
+// past commitHeight-MinRetainBlocks or commitHeight-Evidence.MaxAgeNumBlocks,
+// whichever is smaller (i.e. retains more blocks). This mirrors the Cosmos
+// SDK's GetBlockRetentionHeight: the operator-configured floor is honored,
+// but evidence verification must always be possible, so it takes precedence.
+func (p *Pruner) capRetainHeight(retainHeight int64) int64 {
+	if retainHeight <= 0 {
+		return retainHeight
+	}
+
+	commitHeight := p.bs.Height()
+	retainHeight = capRetainHeightByMinRetainBlocks(retainHeight, commitHeight, p.minRetainBlocks)
+
+	state, err := p.stateStore.Load()
 	if err != nil {
-		if err == ErrKeyNotFound {
-			noAppRetainHeightSet = true
-		} else {
+		p.logger.Error("Failed to load state, cannot cap retain height against evidence age", "err", err)
+		return retainHeight
+	}
+	return capRetainHeightByEvidenceAge(retainHeight, commitHeight, state.ConsensusParams.Evidence.MaxAgeNumBlocks)
+}
+
+// capRetainHeightByMinRetainBlocks lowers retainHeight, if necessary, so
+// that it never goes past commitHeight-minRetainBlocks. If that floor is at
+// or below zero (fewer than minRetainBlocks blocks exist), 0 is returned,
+// i.e. don't prune. It is pure (no DB or block store access) so the floor
+// math can be unit tested directly.
+func capRetainHeightByMinRetainBlocks(retainHeight, commitHeight int64, minRetainBlocks uint64) int64 {
+	if minRetainBlocks == 0 {
+		return retainHeight
+	}
+	if minRetainHeight := commitHeight - int64(minRetainBlocks); minRetainHeight < retainHeight {
+		if minRetainHeight <= 0 {
 			return 0
 		}
+		return minRetainHeight
 	}
-	dcRetainHeight, err := p.stateStore.GetCompanionBlockRetainHeight()
-	if err != nil {
-		if err == ErrKeyNotFound {
-			// The Application height was set so we can return that immediately
-			if !noAppRetainHeightSet {
-				return appRetainHeight
-			}
-		} else {
+	return retainHeight
+}
+
+// capRetainHeightByEvidenceAge lowers retainHeight, if necessary, so that it
+// never goes past commitHeight-maxAgeNumBlocks, ensuring evidence can still
+// be verified for any block old enough to be pruned. If that floor is at or
+// below zero, 0 is returned, i.e. don't prune. It is pure (no DB or block
+// store access) so the floor math can be unit tested directly.
+func capRetainHeightByEvidenceAge(retainHeight, commitHeight, maxAgeNumBlocks int64) int64 {
+	if evRetainHeight := commitHeight - maxAgeNumBlocks; evRetainHeight < retainHeight {
+		if evRetainHeight <= 0 {
 			return 0
 		}
+		return evRetainHeight
 	}
-	// If we are here, both heights were set so we are picking the minimum
-	if appRetainHeight < dcRetainHeight {
-		return appRetainHeight
-	}
-	return dcRetainHeight
+	return retainHeight
 }
 
+// pruneBlocks prunes blocks (and the corresponding state) below height;
+// height itself is retained. If PrunerBatchSize is configured, the [base, height]
+// range is drained in chunks of at most that many heights rather than in
+// one call, so a single pruning pass doesn't hold the block store's write
+// lock for the whole range at once -- important the first time a node
+// prunes after a long time, or after an operator raises the retain height
+// by millions of blocks. Progress is persisted and observers notified
+// after every batch (not just at the end), and Quit() is checked between
+// batches so a large backlog can be interrupted promptly on shutdown
+// instead of running to completion. It returns the total number of blocks
+// pruned and the evidence retain height from the last batch processed.
 func (p *Pruner) pruneBlocks(height int64) (pruned uint64, evRetainHeight int64, err error) {
 	if height <= 0 {
 		return 0, 0, errors.New("retain height cannot be less or equal than 0")
 	}
 
-	base := p.bs.Base()
-
 	var state State
 	state, err = p.stateStore.Load()
 	if err != nil {
@@ -248,15 +684,52 @@ func (p *Pruner) pruneBlocks(height int64) (pruned uint64, evRetainHeight int64,
 		return
 	}
 
-	pruned, evRetainHeight, err = p.bs.PruneBlocks(height, state)
-	if err != nil {
-		p.logger.Error("Failed to prune blocks at height", "height", height, "err", err)
-	} else {
-		p.logger.Debug("Pruned blocks", "pruned", pruned, "retain_height", height)
+	from := p.bs.Base()
+	for from < height {
+		select {
+		case <-p.Quit():
+			return pruned, evRetainHeight, nil
+		default:
+		}
+
+		to := nextPruneBatchEnd(from, height, p.batchSize)
+
+		var batchPruned uint64
+		var batchEvRetainHeight int64
+		batchPruned, batchEvRetainHeight, err = p.bs.PruneBlocks(to, state)
+		if err != nil {
+			p.logger.Error("Failed to prune blocks at height", "height", to, "err", err)
+			return pruned, evRetainHeight, err
+		}
+		if err = p.stateStore.PruneStates(from, to, batchEvRetainHeight); err != nil {
+			p.logger.Error("Failed to prune the state store", "err", err)
+			return pruned, evRetainHeight, err
+		}
+		p.logger.Debug("Pruned blocks", "pruned", batchPruned, "retain_height", to)
+
+		pruned += batchPruned
+		evRetainHeight = batchEvRetainHeight
+
+		if serr := p.stateStore.SaveLastBlockPrunedHeight(to); serr != nil {
+			p.logger.Error("Failed to persist last pruned block height", "err", serr)
+		}
+		for _, o := range p.observers {
+			o.PrunedBlocks(from, to, batchPruned)
+		}
+
+		from = to
 	}
-	err = p.stateStore.PruneStates(base, height, evRetainHeight)
-	if err != nil {
-		p.logger.Error("Failed to prune the state store", "err", err)
+	return pruned, evRetainHeight, nil
+}
+
+// nextPruneBatchEnd returns the end height of the next prune batch, given
+// how far pruning has progressed so far (from), the overall target
+// (height), and the configured batch size (0 meaning unlimited, i.e. prune
+// the whole range in one batch). It is pure so the batching boundary math
+// can be unit tested without a BlockStore/Store.
+func nextPruneBatchEnd(from, height int64, batchSize uint64) int64 {
+	if batchSize > 0 && height-from > int64(batchSize) {
+		return from + int64(batchSize)
 	}
-	return pruned, evRetainHeight, err
+	return height
 }
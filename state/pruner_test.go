@@ -0,0 +1,121 @@
+package state
+
+import "testing"
+
+func TestCapRetainHeightByMinRetainBlocks(t *testing.T) {
+	testCases := []struct {
+		name            string
+		retainHeight    int64
+		commitHeight    int64
+		minRetainBlocks uint64
+		expect          int64
+	}{
+		{"disabled", 100, 200, 0, 100},
+		{"floor not reached", 100, 150, 10, 100},
+		{"floor lowers retain height", 190, 200, 50, 150},
+		{"floor at or below zero disables pruning", 50, 10, 1000, 0},
+		{"floor equal to retain height is ignored", 100, 200, 100, 100},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := capRetainHeightByMinRetainBlocks(tc.retainHeight, tc.commitHeight, tc.minRetainBlocks)
+			if got != tc.expect {
+				t.Errorf("capRetainHeightByMinRetainBlocks(%d, %d, %d) = %d, want %d",
+					tc.retainHeight, tc.commitHeight, tc.minRetainBlocks, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestCapRetainHeightByEvidenceAge(t *testing.T) {
+	testCases := []struct {
+		name            string
+		retainHeight    int64
+		commitHeight    int64
+		maxAgeNumBlocks int64
+		expect          int64
+	}{
+		{"evidence window not reached", 100, 150, 10, 100},
+		{"evidence window lowers retain height", 190, 200, 100, 100},
+		{"evidence floor at or below zero disables pruning", 50, 10, 1000, 0},
+		{"evidence floor equal to retain height is ignored", 100, 200, 100, 100},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := capRetainHeightByEvidenceAge(tc.retainHeight, tc.commitHeight, tc.maxAgeNumBlocks)
+			if got != tc.expect {
+				t.Errorf("capRetainHeightByEvidenceAge(%d, %d, %d) = %d, want %d",
+					tc.retainHeight, tc.commitHeight, tc.maxAgeNumBlocks, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestCapRetainHeightByMinRetainBlocksAndEvidenceAgeTakesTheSmaller(t *testing.T) {
+	// Evidence age and MinRetainBlocks are applied independently by
+	// capRetainHeight, each only ever lowering the retain height further, so
+	// whichever floor retains more blocks (the smaller resulting height)
+	// wins overall regardless of application order.
+	const commitHeight = 1000
+
+	byMinRetainBlocks := capRetainHeightByMinRetainBlocks(900, commitHeight, 200)
+	if byMinRetainBlocks != 800 {
+		t.Fatalf("capRetainHeightByMinRetainBlocks = %d, want 800", byMinRetainBlocks)
+	}
+	byEvidenceAge := capRetainHeightByEvidenceAge(byMinRetainBlocks, commitHeight, 500)
+	if byEvidenceAge != 500 {
+		t.Fatalf("capRetainHeightByEvidenceAge = %d, want 500", byEvidenceAge)
+	}
+}
+
+func TestNextPruneBatchEnd(t *testing.T) {
+	testCases := []struct {
+		name      string
+		from      int64
+		height    int64
+		batchSize uint64
+		expect    int64
+	}{
+		{"unlimited batch size prunes the whole range at once", 0, 1000, 0, 1000},
+		{"range smaller than batch size prunes the whole range at once", 900, 1000, 500, 1000},
+		{"range larger than batch size is capped to one batch", 0, 1000, 300, 300},
+		{"range exactly one batch size is not split further", 0, 300, 300, 300},
+		{"last partial batch is capped to height, not a full batch", 900, 1000, 300, 1000},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := nextPruneBatchEnd(tc.from, tc.height, tc.batchSize)
+			if got != tc.expect {
+				t.Errorf("nextPruneBatchEnd(%d, %d, %d) = %d, want %d",
+					tc.from, tc.height, tc.batchSize, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestNextPruneBatchEndDrainsRangeInBoundedSteps(t *testing.T) {
+	// Repeatedly applying nextPruneBatchEnd from p.bs.Base() must reach
+	// height in a bounded number of steps, each advancing by at most
+	// batchSize, mirroring how pruneBlocks' loop consumes it.
+	const from0, height, batchSize = 0, 1000, 300
+
+	from := int64(from0)
+	steps := 0
+	for from < height {
+		to := nextPruneBatchEnd(from, height, batchSize)
+		if to <= from {
+			t.Fatalf("nextPruneBatchEnd did not advance: from=%d to=%d", from, to)
+		}
+		if to-from > batchSize {
+			t.Fatalf("batch advanced by %d, want at most %d", to-from, batchSize)
+		}
+		from = to
+		steps++
+		if steps > height/batchSize+1 {
+			t.Fatalf("did not converge to height=%d after %d steps, from=%d", height, steps, from)
+		}
+	}
+	if from != height {
+		t.Fatalf("final from = %d, want %d", from, height)
+	}
+}
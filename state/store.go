@@ -0,0 +1,231 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	dbm "github.com/cometbft/cometbft-db"
+
+	cmtstate "github.com/cometbft/cometbft/api/cometbft/state/v1"
+)
+
+var stateKey = []byte("stateKey")
+
+// Store defines the state store persistence used by consensus and by the
+// Pruner: loading/saving State, and tracking the retain heights and pruning
+// progress for every source that can request pruning (application, data
+// companion, ABCI responses, and state-sync snapshots).
+type Store interface {
+	// Load loads the current state of the blockchain.
+	Load() (State, error)
+
+	// PruneStates deletes states between the given heights (including
+	// from, excluding to). Consensus params and ABCI responses are not
+	// deleted by it.
+	PruneStates(from, to, evidenceThresholdHeight int64) error
+
+	// GetApplicationRetainHeight returns the retain height set by the
+	// application, via Pruner.SetApplicationRetainHeight.
+	GetApplicationRetainHeight() (int64, error)
+	// SaveApplicationRetainHeight persists the application retain height.
+	SaveApplicationRetainHeight(height int64) error
+
+	// GetCompanionBlockRetainHeight returns the block retain height set by
+	// a data companion, via Pruner.SetCompanionRetainHeight.
+	GetCompanionBlockRetainHeight() (int64, error)
+	// SaveCompanionBlockRetainHeight persists the data companion retain
+	// height.
+	SaveCompanionBlockRetainHeight(height int64) error
+
+	// GetABCIResRetainHeight returns the retain height for ABCI responses.
+	GetABCIResRetainHeight() (int64, error)
+	// SaveABCIResRetainHeight persists the ABCI response retain height.
+	SaveABCIResRetainHeight(height int64) error
+	// PruneABCIResponses removes the stored ABCI responses for heights
+	// below the given height and returns how many were removed.
+	PruneABCIResponses(height int64) (uint64, error)
+
+	// GetSnapshotRetainHeight returns the retain height required to keep
+	// serving state-sync snapshot chunks, via
+	// Pruner.SetSnapshotRetainHeight.
+	GetSnapshotRetainHeight() (int64, error)
+	// SaveSnapshotRetainHeight persists the snapshot retain height.
+	SaveSnapshotRetainHeight(height int64) error
+
+	// GetLastBlockPrunedHeight returns the height block pruning last
+	// completed up to, so a restart can resume instead of re-scanning from
+	// scratch.
+	GetLastBlockPrunedHeight() (int64, error)
+	// SaveLastBlockPrunedHeight persists the height block pruning last
+	// completed up to.
+	SaveLastBlockPrunedHeight(height int64) error
+
+	// GetLastABCIResPrunedHeight returns the height ABCI response pruning
+	// last completed up to.
+	GetLastABCIResPrunedHeight() (int64, error)
+	// SaveLastABCIResPrunedHeight persists the height ABCI response
+	// pruning last completed up to.
+	SaveLastABCIResPrunedHeight(height int64) error
+}
+
+// dbStore wraps a key-value database to implement Store.
+type dbStore struct {
+	db dbm.DB
+}
+
+// NewStore creates a new dbStore backed by db.
+func NewStore(db dbm.DB) Store {
+	return dbStore{db: db}
+}
+
+func (store dbStore) Load() (State, error) {
+	buf, err := store.db.Get(stateKey)
+	if err != nil {
+		return State{}, err
+	}
+	if len(buf) == 0 {
+		return State{}, nil
+	}
+
+	sp := new(cmtstate.State)
+	if err := sp.Unmarshal(buf); err != nil {
+		panic(fmt.Sprintf("LoadState: data has been corrupted or its spec has changed: %v", err))
+	}
+	sm, err := FromProto(sp)
+	if err != nil {
+		return State{}, err
+	}
+	return *sm, nil
+}
+
+// getHeight and saveHeight implement the simple "store an int64 height
+// under a fixed key" pattern shared by every retain-height and
+// pruning-progress accessor below.
+func (store dbStore) getHeight(key []byte) (int64, error) {
+	buf, err := store.db.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) == 0 {
+		return 0, ErrKeyNotFound
+	}
+	var height int64
+	if _, err := fmt.Sscanf(string(buf), "%d", &height); err != nil {
+		return 0, fmt.Errorf("invalid height value for key %q: %w", key, err)
+	}
+	return height, nil
+}
+
+func (store dbStore) saveHeight(key []byte, height int64) error {
+	return store.db.Set(key, []byte(fmt.Sprintf("%d", height)))
+}
+
+func (store dbStore) GetApplicationRetainHeight() (int64, error) {
+	return store.getHeight(AppRetainHeightKey)
+}
+
+func (store dbStore) SaveApplicationRetainHeight(height int64) error {
+	return store.saveHeight(AppRetainHeightKey, height)
+}
+
+func (store dbStore) GetCompanionBlockRetainHeight() (int64, error) {
+	return store.getHeight(CompanionBlockRetainHeightKey)
+}
+
+func (store dbStore) SaveCompanionBlockRetainHeight(height int64) error {
+	return store.saveHeight(CompanionBlockRetainHeightKey, height)
+}
+
+func (store dbStore) GetABCIResRetainHeight() (int64, error) {
+	return store.getHeight(ABCIResultsRetainHeightKey)
+}
+
+func (store dbStore) SaveABCIResRetainHeight(height int64) error {
+	return store.saveHeight(ABCIResultsRetainHeightKey, height)
+}
+
+func (store dbStore) GetSnapshotRetainHeight() (int64, error) {
+	return store.getHeight(SnapshotBlockRetainHeightKey)
+}
+
+func (store dbStore) SaveSnapshotRetainHeight(height int64) error {
+	return store.saveHeight(SnapshotBlockRetainHeightKey, height)
+}
+
+func (store dbStore) GetLastBlockPrunedHeight() (int64, error) {
+	return store.getHeight(LastBlockPrunedHeightKey)
+}
+
+func (store dbStore) SaveLastBlockPrunedHeight(height int64) error {
+	return store.saveHeight(LastBlockPrunedHeightKey, height)
+}
+
+func (store dbStore) GetLastABCIResPrunedHeight() (int64, error) {
+	return store.getHeight(LastABCIResPrunedHeightKey)
+}
+
+func (store dbStore) SaveLastABCIResPrunedHeight(height int64) error {
+	return store.saveHeight(LastABCIResPrunedHeightKey, height)
+}
+
+// PruneABCIResponses removes the stored ABCI responses for heights below
+// height, returning how many were removed.
+func (store dbStore) PruneABCIResponses(height int64) (uint64, error) {
+	return pruneHeightRange(store.db, 0, height, abciResponsesKey)
+}
+
+// PruneStates deletes states between the given heights (including from,
+// excluding to). evidenceThresholdHeight is not used: every state in that
+// range is deleted regardless of it.
+func (store dbStore) PruneStates(from, to, evidenceThresholdHeight int64) error {
+	if from <= 0 || to <= from {
+		return errors.New("from height must be greater than 0 and to must be greater than from")
+	}
+	_, err := pruneHeightRange(store.db, from, to, stateKeyAtHeight)
+	return err
+}
+
+func stateKeyAtHeight(height int64) []byte {
+	return []byte(fmt.Sprintf("stateKey:%d", height))
+}
+
+func abciResponsesKey(height int64) []byte {
+	return []byte(fmt.Sprintf("abciResponsesKey:%d", height))
+}
+
+// pruneHeightRange deletes the key keyAt(h) for every height h in
+// [from, to), reporting how many were actually present and removed.
+//
+// This deletes height by height rather than driving a db.Iterator over
+// [keyAt(from), keyAt(to)): these keys embed the height as unpadded decimal
+// ASCII (e.g. "stateKey:9", "stateKey:15"), so byte-lexicographic iterator
+// order does not match numeric height order once digit counts differ
+// ("stateKey:9" > "stateKey:15"). A range iterator over such keys can both
+// silently skip the intended range (when from/to straddle a power-of-10
+// boundary) and delete heights outside of it, i.e. exactly the retain
+// heights the pruner floors are meant to protect. Iterating by height
+// avoids the ordering assumption entirely.
+func pruneHeightRange(db dbm.DB, from, to int64, keyAt func(int64) []byte) (uint64, error) {
+	batch := db.NewBatch()
+	defer batch.Close()
+
+	var pruned uint64
+	for h := from; h < to; h++ {
+		key := keyAt(h)
+		has, err := db.Has(key)
+		if err != nil {
+			return pruned, err
+		}
+		if !has {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	if err := batch.Write(); err != nil {
+		return pruned, err
+	}
+	return pruned, nil
+}
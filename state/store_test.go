@@ -0,0 +1,104 @@
+package state
+
+import (
+	"testing"
+
+	dbm "github.com/cometbft/cometbft-db"
+)
+
+func TestPruneHeightRangeCrossesPowerOfTenBoundary(t *testing.T) {
+	// A byte-lexicographic range iterator over these keys would get this
+	// wrong in both directions: "stateKey:9" sorts after "stateKey:15", so
+	// an iterator bounded by [keyAt(9), keyAt(15)) would see its start sort
+	// after its end and prune nothing; and "stateKey:100" sorts before
+	// "stateKey:15", so a range bounded by keyAt(15) would delete height
+	// 100 anyway even though it's well outside [9, 15). Iterating by height
+	// must get both cases right.
+	db := dbm.NewMemDB()
+
+	for _, h := range []int64{9, 10, 14, 15, 100} {
+		if err := db.Set(stateKeyAtHeight(h), []byte("x")); err != nil {
+			t.Fatalf("Set(%d): %v", h, err)
+		}
+	}
+
+	pruned, err := pruneHeightRange(db, 9, 15, stateKeyAtHeight)
+	if err != nil {
+		t.Fatalf("pruneHeightRange: %v", err)
+	}
+	if pruned != 3 {
+		t.Fatalf("pruned = %d, want 3 (heights 9, 10, 14)", pruned)
+	}
+
+	for _, h := range []int64{9, 10, 14} {
+		has, err := db.Has(stateKeyAtHeight(h))
+		if err != nil {
+			t.Fatalf("Has(%d): %v", h, err)
+		}
+		if has {
+			t.Errorf("height %d still present, want pruned", h)
+		}
+	}
+	for _, h := range []int64{15, 100} {
+		has, err := db.Has(stateKeyAtHeight(h))
+		if err != nil {
+			t.Fatalf("Has(%d): %v", h, err)
+		}
+		if !has {
+			t.Errorf("height %d was pruned, want retained (outside [9, 15))", h)
+		}
+	}
+}
+
+func TestPruneHeightRangeSkipsAbsentHeights(t *testing.T) {
+	db := dbm.NewMemDB()
+	if err := db.Set(abciResponsesKey(5), []byte("x")); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	pruned, err := pruneHeightRange(db, 0, 10, abciResponsesKey)
+	if err != nil {
+		t.Fatalf("pruneHeightRange: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("pruned = %d, want 1", pruned)
+	}
+}
+
+func TestStorePruneABCIResponses(t *testing.T) {
+	db := dbm.NewMemDB()
+	store := NewStore(db)
+
+	for _, h := range []int64{9, 15, 100} {
+		if err := db.Set(abciResponsesKey(h), []byte("x")); err != nil {
+			t.Fatalf("Set(%d): %v", h, err)
+		}
+	}
+
+	pruned, err := store.PruneABCIResponses(15)
+	if err != nil {
+		t.Fatalf("PruneABCIResponses: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("pruned = %d, want 1 (height 9 only)", pruned)
+	}
+
+	has, err := db.Has(abciResponsesKey(100))
+	if err != nil {
+		t.Fatalf("Has: %v", err)
+	}
+	if !has {
+		t.Errorf("height 100 was pruned, want retained (above the requested retain height)")
+	}
+}
+
+func TestStorePruneStatesRejectsInvalidRange(t *testing.T) {
+	store := NewStore(dbm.NewMemDB())
+
+	if err := store.PruneStates(0, 10, 0); err == nil {
+		t.Error("PruneStates(0, 10, 0) = nil error, want error (from must be > 0)")
+	}
+	if err := store.PruneStates(10, 10, 0); err == nil {
+		t.Error("PruneStates(10, 10, 0) = nil error, want error (to must be > from)")
+	}
+}